@@ -2,16 +2,25 @@ package shell
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 )
 
+// Shell, Panic, Trace, and TracePrefix are the package's defaults: every
+// Command that doesn't override its own Options via Command.With reads
+// them. The package's own reads of these (and of the installed Tracer) are
+// guarded by globalsMu, but direct assignment from multiple goroutines
+// (e.g. `shell.Panic = false`) is still a data race - use a Session or
+// Command.With for concurrency-safe per-command configuration instead.
 var (
 	Shell       = []string{"/bin/sh", "-c"}
 	Panic       = true
@@ -19,9 +28,88 @@ var (
 	TracePrefix = "+"
 
 	exit = os.Exit
+
+	tracer    Tracer = NewTextTracer(os.Stderr)
+	globalsMu sync.RWMutex
 )
 
-var Tee io.Writer
+// Options captures the settings normally read from the package's mutable
+// globals, so a Command or Session can run with its own configuration
+// instead of racing on package state. Use DefaultOptions to start from the
+// package's current defaults. Trace and TracePrefix are not read from
+// Options: the tracer from NewTextTracer reads the package-level Trace and
+// TracePrefix.
+type Options struct {
+	Shell       []string
+	Panic       bool
+	Trace       bool
+	TracePrefix string
+	Tracer      Tracer
+}
+
+// DefaultOptions returns a snapshot of the package's current default
+// Options, read under the lock that guards the globals.
+func DefaultOptions() Options {
+	globalsMu.RLock()
+	defer globalsMu.RUnlock()
+	return Options{
+		Shell:       Shell,
+		Panic:       Panic,
+		Trace:       Trace,
+		TracePrefix: TracePrefix,
+		Tracer:      tracer,
+	}
+}
+
+// Tracer receives start/exit notifications for every command shell runs,
+// replacing the package's former unconditional log.Println(cmd.Args). Use
+// SetTracer to install one, e.g. to emit structured logs or measure
+// per-command timing.
+type Tracer interface {
+	OnStart(cmd []string, wd string)
+	OnExit(cmd []string, exit int, dur time.Duration, err error)
+}
+
+// SetTracer installs t as the package's tracer. A nil t installs a no-op
+// tracer, which also turns off the Trace output of the initial
+// NewTextTracer(os.Stderr) tracer.
+func SetTracer(t Tracer) {
+	if t == nil {
+		t = noopTracer{}
+	}
+	globalsMu.Lock()
+	tracer = t
+	globalsMu.Unlock()
+}
+
+type noopTracer struct{}
+
+func (noopTracer) OnStart(cmd []string, wd string)                             {}
+func (noopTracer) OnExit(cmd []string, exit int, dur time.Duration, err error) {}
+
+// textTracer prints shell's debug trace: it prints the command to w, gated
+// by the Trace flag, when a command starts.
+type textTracer struct {
+	w io.Writer
+}
+
+// NewTextTracer returns a Tracer that writes "TracePrefix <argv>" to w
+// whenever Trace is true. argv is the full argument list of the process, so
+// for a shell command it includes Shell, e.g. "+ /bin/sh -c echo foo".
+func NewTextTracer(w io.Writer) Tracer {
+	return &textTracer{w: w}
+}
+
+func (t *textTracer) OnStart(cmd []string, wd string) {
+	globalsMu.RLock()
+	trace, prefix := Trace, TracePrefix
+	globalsMu.RUnlock()
+	if trace {
+		fmt.Fprintln(t.w, prefix, strings.Join(cmd, " "))
+	}
+}
+
+func (t *textTracer) OnExit(cmd []string, exit int, dur time.Duration, err error) {}
 
 func assert(err error) {
 	if err != nil {
@@ -58,11 +146,37 @@ type Command struct {
 	args []string
 	in   *Command
 	wd   string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	env      map[string]string
+	clearEnv bool
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+
+	execMode bool
+	shell    []string
+
+	opts *Options
+}
+
+// clone returns a copy of c, used as the starting point for the *Fn helpers;
+// its argument slice is copied so they don't share argument slices with c.
+func (c *Command) clone() *Command {
+	return &Command{
+		args: append([]string(nil), c.args...), in: c.in, wd: c.wd, ctx: c.ctx,
+		env: c.env, clearEnv: c.clearEnv,
+		stdin: c.stdin, stdout: c.stdout, stderr: c.stderr,
+		execMode: c.execMode, shell: c.shell,
+		opts: c.opts,
+	}
 }
 
 func (c *Command) ProcFn() func(...interface{}) *Process {
 	return func(args ...interface{}) *Process {
-		cmd := &Command{c.args, c.in, c.wd}
+		cmd := c.clone()
 		cmd.addArgs(args...)
 		return cmd.Run()
 	}
@@ -70,7 +184,7 @@ func (c *Command) ProcFn() func(...interface{}) *Process {
 
 func (c *Command) OutputFn() func(...interface{}) (string, error) {
 	return func(args ...interface{}) (out string, err error) {
-		cmd := &Command{c.args, c.in, c.wd}
+		cmd := c.clone()
 		cmd.addArgs(args...)
 		defer func() {
 			if p, ok := recover().(*Process); p != nil {
@@ -88,7 +202,7 @@ func (c *Command) OutputFn() func(...interface{}) (string, error) {
 
 func (c *Command) ErrFn() func(...interface{}) error {
 	return func(args ...interface{}) (err error) {
-		cmd := &Command{c.args, c.in, c.wd}
+		cmd := c.clone()
 		cmd.addArgs(args...)
 		defer func() {
 			if p, ok := recover().(*Process); p != nil {
@@ -113,20 +227,157 @@ func (c *Command) SetWorkDir(path string) *Command {
 	return c
 }
 
+// WithContext attaches ctx to c so that the underlying process (and any
+// upstream commands piped into it) are killed when ctx is done.
+func (c *Command) WithContext(ctx context.Context) *Command {
+	c.ctx = ctx
+	return c
+}
+
+// SetTimeout is a convenience around WithContext that kills the command if
+// it hasn't finished within d.
+func (c *Command) SetTimeout(d time.Duration) *Command {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	c.ctx = ctx
+	c.cancel = cancel
+	return c
+}
+
+// SetEnv sets additional environment variables for c, merged onto
+// os.Environ() unless ClearEnv was called.
+func (c *Command) SetEnv(env map[string]string) *Command {
+	if c.env == nil {
+		c.env = make(map[string]string, len(env))
+	}
+	for k, v := range env {
+		c.env[k] = v
+	}
+	return c
+}
+
+// AppendEnv sets a single environment variable, see SetEnv.
+func (c *Command) AppendEnv(k, v string) *Command {
+	return c.SetEnv(map[string]string{k: v})
+}
+
+// ClearEnv makes c start from an empty environment instead of merging onto
+// os.Environ().
+func (c *Command) ClearEnv() *Command {
+	c.clearEnv = true
+	return c
+}
+
+// SetStdin uses r as the command's stdin instead of the default stdin pipe.
+func (c *Command) SetStdin(r io.Reader) *Command {
+	c.stdin = r
+	return c
+}
+
+// SetStdout streams the command's stdout to w in addition to the internal
+// buffer backing Process.String/Bytes.
+func (c *Command) SetStdout(w io.Writer) *Command {
+	c.stdout = w
+	return c
+}
+
+// SetStderr streams the command's stderr to w in addition to the internal
+// buffer backing Process.Error.
+func (c *Command) SetStderr(w io.Writer) *Command {
+	c.stderr = w
+	return c
+}
+
+// Tee streams both stdout and stderr to w, see SetStdout and SetStderr.
+func (c *Command) Tee(w io.Writer) *Command {
+	c.SetStdout(w)
+	c.SetStderr(w)
+	return c
+}
+
+// SetShell overrides the package-level Shell for c alone, e.g. to run a
+// specific command through "bash -c" without mutating Shell for every
+// other command.
+func (c *Command) SetShell(shell []string) *Command {
+	c.shell = shell
+	return c
+}
+
+func (c *Command) effectiveShell() []string {
+	if c.shell != nil {
+		return c.shell
+	}
+	return c.options().Shell
+}
+
+// With scopes opts to c, and to any command piped into it, so c runs with
+// its own Shell/Panic/Tracer instead of the package's mutable globals. See
+// DefaultOptions and Session for building opts.
+func (c *Command) With(opts Options) *Command {
+	c.opts = &opts
+	return c
+}
+
+// options returns the Options in effect for c: its own if With was called,
+// otherwise a snapshot of the package's current globals.
+func (c *Command) options() Options {
+	if c.opts != nil {
+		return *c.opts
+	}
+	return DefaultOptions()
+}
+
+// Exec runs c's arguments as a literal argv, resolved via exec.LookPath,
+// instead of joining them into a string and handing them to Shell. This
+// avoids shell injection when an argument contains spaces or shell
+// metacharacters, and does not need /bin/sh. The package does not build on
+// Windows: buildCmd uses syscall.Kill and SysProcAttr.Setpgid, which exist
+// only on Unix-like systems.
+func (c *Command) Exec() *Process {
+	c.execMode = true
+	return c.Run()
+}
+
+// ExecStart is the Start counterpart to Exec.
+func (c *Command) ExecStart() *Process {
+	c.execMode = true
+	return c.Start()
+}
+
+// String renders the exact command c will run, for debugging/trace.
+func (c *Command) String() string {
+	if c.execMode {
+		quoted := make([]string, len(c.args))
+		for i, arg := range c.args {
+			quoted[i] = Quote(arg)
+		}
+		return strings.Join(quoted, " ")
+	}
+	return strings.Join(c.effectiveShell(), " ") + " " + Quote(c.shellCmd(false))
+}
+
 func (c *Command) addArgs(args ...interface{}) {
 	var strArgs []string
 	for i, arg := range args {
+		// *Command implements fmt.Stringer (via String, for debugging/trace),
+		// so it must be special-cased ahead of that case: as the last
+		// argument it pipes into c, anywhere else it's invalid.
+		if cmd, ok := arg.(*Command); ok {
+			if i+1 == len(args) {
+				c.in = cmd
+				continue
+			}
+			panic("invalid type for argument")
+		}
 		switch v := arg.(type) {
 		case string:
 			strArgs = append(strArgs, v)
 		case fmt.Stringer:
 			strArgs = append(strArgs, v.String())
 		default:
-			cmd, ok := arg.(*Command)
-			if i+1 == len(args) && ok {
-				c.in = cmd
-				continue
-			}
 			panic("invalid type for argument")
 		}
 	}
@@ -144,59 +395,309 @@ func (c *Command) shellCmd(quote bool) string {
 	return strings.Join(quoted, " ")
 }
 
-func (c *Command) Run() *Process {
-	cmd := exec.Command(Shell[0], append(Shell[1:], c.shellCmd(false))...)
-	return c.execute(cmd, cmd.Run)
+func (c *Command) ctxOrBackground() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
 }
 
-func (c *Command) Start() *Process {
-	cmd := exec.Command(Shell[0], append(Shell[1:], c.shellCmd(false))...)
-	return c.execute(cmd, cmd.Start)
+// buildEnv returns the environment to run c with, or nil to let exec.Cmd
+// fall back to os.Environ() unchanged.
+func (c *Command) buildEnv() []string {
+	if c.env == nil && !c.clearEnv {
+		return nil
+	}
+	var env []string
+	if !c.clearEnv {
+		env = os.Environ()
+	}
+	for k, v := range c.env {
+		env = append(env, k+"="+v)
+	}
+	return env
 }
 
-func (c *Command) execute(cmd *exec.Cmd, call func() error) *Process {
-	if Trace {
-		fmt.Fprintln(os.Stderr, TracePrefix, c.shellCmd(false))
+// ctxKillWaitDelay bounds how long cmd.Wait will block on I/O copiers (e.g.
+// the stdout buffer) after a context-cancelled process's pgid has been
+// killed, so Wait returns promptly instead of blocking on a grandchild that
+// inherited the pipe, per exec.Cmd.WaitDelay.
+const ctxKillWaitDelay = 2 * time.Second
+
+// buildCmd builds the *exec.Cmd for c alone, without wiring up stdin/stdout
+// or running it; shared by the single-command path and pipeline stages.
+func (c *Command) buildCmd() *exec.Cmd {
+	var cmd *exec.Cmd
+	if c.execMode {
+		path, err := exec.LookPath(c.args[0])
+		assert(err)
+		cmd = exec.CommandContext(c.ctxOrBackground(), path, c.args[1:]...)
+	} else {
+		shell := c.effectiveShell()
+		cmd = exec.CommandContext(c.ctxOrBackground(), shell[0], append(shell[1:], c.shellCmd(false))...)
 	}
 	cmd.Dir = c.wd
-	log.Println(cmd.Args)
-	p := new(Process)
-	p.cmd = cmd
-	if c.in != nil {
-		cmd.Stdin = c.in.Run()
-	} else {
-		stdin, err := cmd.StdinPipe()
-		assert(err)
-		p.Stdin = stdin
+	cmd.Env = c.buildEnv()
+	if c.ctx != nil {
+		// Run in its own process group so cancellation can kill the whole
+		// tree, not just the direct child: in shell mode, SIGKILL on cmd's
+		// own pid only ever reaches /bin/sh, not the real command it spawned.
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		cmd.Cancel = func() error {
+			return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		}
+		cmd.WaitDelay = ctxKillWaitDelay
 	}
+	return cmd
+}
+
+// wireStdin gives cmd its stdin: a caller-supplied reader if set, otherwise
+// a pipe the returned Process exposes for writing.
+func (c *Command) wireStdin(cmd *exec.Cmd) io.WriteCloser {
+	if c.stdin != nil {
+		cmd.Stdin = c.stdin
+		return nil
+	}
+	stdin, err := cmd.StdinPipe()
+	assert(err)
+	return stdin
+}
+
+// wireStdout buffers cmd's stdout internally (for Process.String/Bytes),
+// teeing it to a caller-supplied writer if one was set via SetStdout/Tee.
+func (c *Command) wireStdout(cmd *exec.Cmd) *bytes.Buffer {
 	var stdout bytes.Buffer
-	if Tee != nil {
-		cmd.Stdout = io.MultiWriter(&stdout, Tee)
+	if c.stdout != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, c.stdout)
 	} else {
 		cmd.Stdout = &stdout
 	}
-	p.Stdout = &stdout
+	return &stdout
+}
+
+// wireStderr is the stderr counterpart to wireStdout.
+func (c *Command) wireStderr(cmd *exec.Cmd) *bytes.Buffer {
 	var stderr bytes.Buffer
-	if Tee != nil {
-		cmd.Stderr = io.MultiWriter(&stderr, Tee)
+	if c.stderr != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, c.stderr)
 	} else {
 		cmd.Stderr = &stderr
 	}
-	p.Stderr = &stderr
+	return &stderr
+}
+
+func (c *Command) Run() *Process {
+	if c.in != nil {
+		return c.runPipeline()
+	}
+	cmd := c.buildCmd()
+	p := c.execute(cmd, cmd.Run)
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return p
+}
+
+func (c *Command) Start() *Process {
+	if c.in != nil {
+		return c.startPipeline()
+	}
+	cmd := c.buildCmd()
+	p := c.execute(cmd, cmd.Start)
+	p.cancel = c.cancel
+	return p
+}
+
+func (c *Command) execute(cmd *exec.Cmd, call func() error) *Process {
+	opts := c.options()
+	opts.Tracer.OnStart(cmd.Args, c.wd)
+	p := new(Process)
+	p.cmd = cmd
+	p.ctx = c.ctx
+	p.opts = opts
+	p.traced = true
+	p.traceStart = time.Now()
+	p.Stdin = c.wireStdin(cmd)
+	p.Stdout = c.wireStdout(cmd)
+	p.Stderr = c.wireStderr(cmd)
 	err := call()
-	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			if stat, ok := exiterr.Sys().(syscall.WaitStatus); ok {
-				p.ExitStatus = int(stat.ExitStatus())
-				if Panic {
-					panic(p)
-				}
-			}
+	if cmd.ProcessState != nil {
+		// call was cmd.Run, which already waited for the process to exit;
+		// cmd.Start leaves ProcessState nil until Process.Wait observes it.
+		p.applyExit(err, true)
+	} else if err != nil {
+		assert(err)
+	}
+	return p
+}
+
+// chain returns the commands piped into c, from the most upstream command
+// to c itself, propagating ctx from downstream commands onto any upstream
+// command that doesn't have its own.
+func (c *Command) chain() []*Command {
+	var reverse []*Command
+	for cur := c; cur != nil; cur = cur.in {
+		reverse = append(reverse, cur)
+	}
+	chain := make([]*Command, len(reverse))
+	for i, cmd := range reverse {
+		chain[len(reverse)-1-i] = cmd
+	}
+	for i := len(chain) - 1; i > 0; i-- {
+		if chain[i-1].ctx == nil {
+			chain[i-1].ctx = chain[i].ctx
+		}
+		if chain[i-1].opts == nil {
+			chain[i-1].opts = chain[i].opts
+		}
+	}
+	return chain
+}
+
+// Pipeline is a chain of commands connected stdout-to-stdin, built from a
+// Command constructed via Pipe. Unlike running each stage to completion
+// before starting the next, a Pipeline streams: every stage starts up
+// front, and data flows between them as it's produced.
+type Pipeline struct {
+	stages  []*Process
+	osPipes []*os.File // parent-side fds to close once every stage is started
+	closers []io.Closer
+}
+
+// Stages returns the Pipeline's Processes in order, most upstream first,
+// so callers can inspect ExitStatus for any stage, not just the last.
+func (pl *Pipeline) Stages() []*Process {
+	return pl.stages
+}
+
+// Start starts every stage in order and returns as soon as they're all
+// running; it does not wait for any of them to finish. The parent's copy of
+// each inter-stage pipe fd is closed once every stage has started, so that
+// a downstream stage exiting early delivers SIGPIPE to its upstream, same
+// as a shell pipeline.
+func (pl *Pipeline) Start() error {
+	for _, p := range pl.stages {
+		if err := p.cmd.Start(); err != nil {
+			return err
+		}
+	}
+	for _, f := range pl.osPipes {
+		f.Close()
+	}
+	return nil
+}
+
+// Wait waits for every stage to finish and returns their errors joined
+// together, or nil if every stage exited cleanly.
+func (pl *Pipeline) Wait() error {
+	var errs []error
+	for i, p := range pl.stages {
+		if err := p.wait(); err != nil {
+			errs = append(errs, err)
+		}
+		if pl.closers[i] != nil {
+			pl.closers[i].Close()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run starts every stage and waits for the pipeline to finish.
+func (pl *Pipeline) Run() error {
+	if err := pl.Start(); err != nil {
+		return err
+	}
+	return pl.Wait()
+}
+
+// Pipeline builds, but does not start, the Pipeline for c's chain of piped
+// commands. If c was not built with Pipe, the Pipeline has a single stage.
+//
+// Stages are connected with os.Pipe so the OS streams data between them
+// directly, the same way a shell pipeline does; SetStdout/Tee on a
+// non-terminal stage falls back to an in-process pipe so the tee'd writer
+// still sees the data.
+func (c *Command) Pipeline() *Pipeline {
+	chain := c.chain()
+	pl := &Pipeline{
+		stages:  make([]*Process, len(chain)),
+		closers: make([]io.Closer, len(chain)),
+	}
+	var upstream io.Reader
+	for i, stage := range chain {
+		cmd := stage.buildCmd()
+		p := new(Process)
+		p.cmd = cmd
+		p.ctx = stage.ctx
+		p.opts = stage.options()
+		p.opts.Tracer.OnStart(cmd.Args, stage.wd)
+		p.traced = true
+		p.traceStart = time.Now()
+
+		if i == 0 {
+			p.Stdin = stage.wireStdin(cmd)
 		} else {
+			cmd.Stdin = upstream
+		}
+
+		if i == len(chain)-1 {
+			p.Stdout = stage.wireStdout(cmd)
+		} else if stage.stdout != nil {
+			pr, pw := io.Pipe()
+			cmd.Stdout = io.MultiWriter(pw, stage.stdout)
+			pl.closers[i] = pw
+
+			// Bridge pr into the next stage over an os.Pipe instead of
+			// handing it pr directly: if the downstream stage exits early,
+			// closing its end of an os.Pipe is what lets the copy below
+			// fail and close pr with an error, which unblocks pw.Write
+			// above instead of letting it (and this stage's Wait) hang
+			// forever waiting for a reader that's gone, same as SIGPIPE
+			// would in a real shell.
+			dr, dw, err := os.Pipe()
 			assert(err)
+			pl.osPipes = append(pl.osPipes, dr)
+			go func() {
+				_, err := io.Copy(dw, pr)
+				dw.Close()
+				pr.CloseWithError(err)
+			}()
+			upstream = dr
+			p.Stdout = new(bytes.Buffer)
+		} else {
+			pr, pw, err := os.Pipe()
+			assert(err)
+			cmd.Stdout = pw
+			pl.osPipes = append(pl.osPipes, pr, pw)
+			upstream = pr
+			p.Stdout = new(bytes.Buffer)
 		}
+		p.Stderr = stage.wireStderr(cmd)
+
+		pl.stages[i] = p
 	}
-	return p
+	return pl
+}
+
+func (c *Command) runPipeline() *Process {
+	pl := c.Pipeline()
+	assert(pl.Start())
+	pl.Wait()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	last := pl.stages[len(pl.stages)-1]
+	last.pipeline = pl
+	return last
+}
+
+func (c *Command) startPipeline() *Process {
+	pl := c.Pipeline()
+	assert(pl.Start())
+	last := pl.stages[len(pl.stages)-1]
+	last.pipeline = pl
+	last.cancel = c.cancel
+	return last
 }
 
 func Cmd(cmd ...interface{}) *Command {
@@ -205,9 +706,22 @@ func Cmd(cmd ...interface{}) *Command {
 	return c
 }
 
+// CmdContext is like Cmd but attaches ctx to the returned Command, see
+// Command.WithContext.
+func CmdContext(ctx context.Context, cmd ...interface{}) *Command {
+	return Cmd(cmd...).WithContext(ctx)
+}
+
 type Process struct {
-	cmd    *exec.Cmd
-	killed bool
+	cmd      *exec.Cmd
+	killed   bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	pipeline *Pipeline
+
+	opts       Options
+	traced     bool
+	traceStart time.Time
 
 	Stdout     *bytes.Buffer
 	Stderr     *bytes.Buffer
@@ -215,19 +729,78 @@ type Process struct {
 	ExitStatus int
 }
 
+// Pipeline returns the Pipeline p is the terminal stage of, or nil if p is
+// a standalone command.
+func (p *Process) Pipeline() *Pipeline {
+	return p.pipeline
+}
+
+// ContextError is returned by Process.Wait when the command was terminated
+// because its context was cancelled or timed out, rather than exiting with
+// a non-zero status on its own.
+type ContextError struct {
+	Args []string
+	Err  error
+}
+
+func (e *ContextError) Error() string {
+	return fmt.Sprintf("shell: %s: %s", strings.Join(e.Args, " "), e.Err)
+}
+
+func (e *ContextError) Unwrap() error {
+	return e.Err
+}
+
 func (p *Process) Wait() error {
+	if p.pipeline != nil {
+		return p.pipeline.Wait()
+	}
+	return p.wait()
+}
+
+func (p *Process) wait() error {
+	if p.cancel != nil {
+		// Deferred so ctx.Err() below reflects why the command ended, not
+		// this cancel.
+		defer p.cancel()
+	}
 	err := p.cmd.Wait()
+	if err != nil && p.ctx != nil && p.ctx.Err() != nil {
+		p.killed = true
+		p.applyExit(err, false)
+		return &ContextError{Args: p.cmd.Args, Err: p.ctx.Err()}
+	}
+	p.applyExit(err, false)
+	return err
+}
+
+// applyExit records err as p's outcome: it sets ExitStatus, notifies the
+// tracer if p was started through Command.Run/Start, and panics if Panic is
+// set. It's shared by the synchronous Run path (execute) and the deferred
+// Wait path (wait). assertUnknown matches each path's original behavior for
+// errors that are neither context-cancellation nor an *exec.ExitError: Run
+// treats them as a bug (assert/panic), Wait just returns them as-is (e.g. a
+// second call to Wait).
+func (p *Process) applyExit(err error, assertUnknown bool) {
+	shouldPanic := false
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
+		if p.ctx != nil && p.ctx.Err() != nil {
+			p.killed = true
+		} else if exiterr, ok := err.(*exec.ExitError); ok {
 			if stat, ok := exiterr.Sys().(syscall.WaitStatus); ok {
 				p.ExitStatus = int(stat.ExitStatus())
-				if Panic && !p.killed {
-					panic(p)
-				}
+				shouldPanic = p.opts.Panic && !p.killed
 			}
+		} else if assertUnknown {
+			assert(err)
 		}
 	}
-	return err
+	if p.traced {
+		p.opts.Tracer.OnExit(p.cmd.Args, p.ExitStatus, time.Since(p.traceStart), err)
+	}
+	if shouldPanic {
+		panic(p)
+	}
 }
 
 func (p *Process) Kill() error {
@@ -278,3 +851,51 @@ func Run(cmd ...interface{}) *Process {
 func Start(cmd ...interface{}) *Process {
 	return Cmd(cmd...).Start()
 }
+
+// Exec is like Run but bypasses Shell, see Command.Exec.
+func Exec(cmd ...interface{}) *Process {
+	return Cmd(cmd...).Exec()
+}
+
+// ExecStart is like Start but bypasses Shell, see Command.Exec.
+func ExecStart(cmd ...interface{}) *Process {
+	return Cmd(cmd...).ExecStart()
+}
+
+// Session bundles Options with a working directory and environment so a
+// caller can build an isolated shell context, independent of the package's
+// mutable globals, and share it safely across goroutines.
+type Session struct {
+	Options Options
+	Dir     string
+	Env     map[string]string
+}
+
+// NewSession returns a Session seeded with the package's current default
+// Options.
+func NewSession() *Session {
+	return &Session{Options: DefaultOptions()}
+}
+
+// Cmd is like the package-level Cmd, scoped to s's Options, working
+// directory, and environment.
+func (s *Session) Cmd(cmd ...interface{}) *Command {
+	c := Cmd(cmd...).With(s.Options)
+	if s.Dir != "" {
+		c.SetWorkDir(s.Dir)
+	}
+	if s.Env != nil {
+		c.SetEnv(s.Env)
+	}
+	return c
+}
+
+// Run is like the package-level Run, scoped to s.
+func (s *Session) Run(cmd ...interface{}) *Process {
+	return s.Cmd(cmd...).Run()
+}
+
+// Start is like the package-level Start, scoped to s.
+func (s *Session) Start(cmd ...interface{}) *Process {
+	return s.Cmd(cmd...).Start()
+}
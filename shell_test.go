@@ -2,6 +2,8 @@ package shell
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -60,6 +62,43 @@ func TestStartKillWait(t *testing.T) {
 	}
 }
 
+func TestSetTimeoutKillsPromptly(t *testing.T) {
+	Panic = false
+	defer func() { Panic = true }()
+
+	start := time.Now()
+	p := Cmd("sleep", "5").SetTimeout(300 * time.Millisecond).Start()
+	err := p.Wait()
+	if dur := time.Since(start); dur > 2*time.Second {
+		t.Fatal("expected the timeout to kill the command promptly, took:", dur)
+	}
+
+	var ctxErr *ContextError
+	if !errors.As(err, &ctxErr) {
+		t.Fatal("expected a *ContextError, got:", err)
+	}
+}
+
+func TestCmdContextCancel(t *testing.T) {
+	Panic = false
+	defer func() { Panic = true }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := CmdContext(ctx, "sleep", "5").Start()
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := p.Wait()
+	if dur := time.Since(start); dur > 2*time.Second {
+		t.Fatal("expected cancellation to kill the command promptly, took:", dur)
+	}
+
+	var ctxErr *ContextError
+	if !errors.As(err, &ctxErr) {
+		t.Fatal("expected a *ContextError, got:", err)
+	}
+}
+
 func TestPanic(t *testing.T) {
 	defer func() {
 		p := recover().(*Process).ExitStatus
@@ -77,6 +116,45 @@ func TestPipe(t *testing.T) {
 	}
 }
 
+func TestPipeStreams(t *testing.T) {
+	Panic = false
+	defer func() { Panic = true }()
+
+	done := make(chan string, 1)
+	go func() {
+		p := Cmd("yes", "hi").Pipe("head", "-n", "1").Run()
+		done <- p.String()
+	}()
+	select {
+	case out := <-done:
+		if out != "hi" {
+			t.Fatal("output not expected:", out)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pipe did not stream: yes never finishes on its own, so a buffering implementation would hang here")
+	}
+}
+
+func TestPipeTeeNonTerminalEarlyExit(t *testing.T) {
+	Panic = false
+	defer func() { Panic = true }()
+
+	var tee bytes.Buffer
+	done := make(chan string, 1)
+	go func() {
+		p := Cmd("yes", "hi").Tee(&tee).Pipe("head", "-n", "1").Run()
+		done <- p.String()
+	}()
+	select {
+	case out := <-done:
+		if out != "hi" {
+			t.Fatal("output not expected:", out)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlocked: tee on a non-terminal stage blocked when the downstream stage exited early")
+	}
+}
+
 func TestSingleArg(t *testing.T) {
 	p := Run("echo foobar | wc -c | awk '{print $1}'")
 	if p.String() != "7" {
@@ -209,3 +287,100 @@ func TestCmdTee(t *testing.T) {
 		t.Errorf("expected Tee output to be (test\\n), but was (%s)", string(out))
 	}
 }
+
+func TestExec(t *testing.T) {
+	p := Exec("echo", "foo bar")
+	if p.String() != "foo bar" {
+		t.Fatal("output not expected:", p.String())
+	}
+}
+
+func TestExecNoShellExpansion(t *testing.T) {
+	p := Exec("echo", "foo; echo bar")
+	if p.String() != "foo; echo bar" {
+		t.Fatal("expected literal argv with no shell interpretation:", p.String())
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	s := Cmd("echo", "foobar").String()
+	if s != "/bin/sh -c 'echo foobar'" {
+		t.Fatal("string not expected:", s)
+	}
+}
+
+type recordingTracer struct {
+	started, exited int
+	lastExit        int
+	lastErr         error
+}
+
+func (r *recordingTracer) OnStart(cmd []string, wd string) {
+	r.started++
+}
+
+func (r *recordingTracer) OnExit(cmd []string, exit int, dur time.Duration, err error) {
+	r.exited++
+	r.lastExit = exit
+	r.lastErr = err
+}
+
+func TestSetTracer(t *testing.T) {
+	rec := &recordingTracer{}
+	SetTracer(rec)
+	defer SetTracer(nil)
+
+	Run("echo", "foobar")
+	if rec.started != 1 || rec.exited != 1 {
+		t.Fatal("expected one start and one exit notification:", rec.started, rec.exited)
+	}
+	if rec.lastExit != 0 || rec.lastErr != nil {
+		t.Fatal("expected a clean exit:", rec.lastExit, rec.lastErr)
+	}
+}
+
+func TestSetTracerPipeline(t *testing.T) {
+	rec := &recordingTracer{}
+	SetTracer(rec)
+	defer SetTracer(nil)
+
+	Cmd("echo", "foobar").Pipe("wc", "-c").Run()
+	if rec.started != 2 || rec.exited != 2 {
+		t.Fatal("expected a start/exit notification per pipeline stage:", rec.started, rec.exited)
+	}
+}
+
+func TestCommandWith(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Panic = false
+
+	defer func() {
+		if recover() != nil {
+			t.Fatal("expected With(opts) to suppress the panic Panic would normally cause")
+		}
+	}()
+	p := Cmd("exit", "2").With(opts).Run()
+	if p.ExitStatus != 2 {
+		t.Fatal("exit status not expected:", p.ExitStatus)
+	}
+}
+
+func TestSessionIsolation(t *testing.T) {
+	s := NewSession()
+	s.Options.Panic = false
+	s.Env = map[string]string{"GOSHELL_TEST": "hi"}
+
+	p := s.Run("echo", "$GOSHELL_TEST")
+	if p.String() != "hi" {
+		t.Fatal("output not expected:", p.String())
+	}
+
+	p = s.Run("exit", "2")
+	if p.ExitStatus != 2 {
+		t.Fatal("exit status not expected:", p.ExitStatus)
+	}
+
+	if Panic != true {
+		t.Fatal("expected package-level Panic to be unaffected by the Session")
+	}
+}